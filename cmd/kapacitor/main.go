@@ -1,19 +1,28 @@
 package main
 
 import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
-	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/influxdb/kapacitor"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
 )
 
 // These variables are populated via the Go linker.
@@ -26,7 +35,7 @@ var (
 var l = log.New(os.Stderr, "[run] ", log.LstdFlags)
 
 var usageStr = `
-Usage: kapacitor [command] [args]
+Usage: kapacitor [options] [command] [args]
 
 Commands:
 
@@ -38,72 +47,104 @@ Commands:
 	push     publish a task definition to another Kapacitor instance.
 	delete   delete a task or a recording.
 	list     list information about tasks or recordings.
+	stats    display runtime statistics about kapacitord.
+	watch    stream logs and alert events for a task as they happen.
 	help     get help for a command.
 	level    sets the logging level on the kapacitord server.
 	version  displays the Kapacitor version info.
+
+Options:
 `
 
 func usage() {
 	fmt.Fprintln(os.Stderr, usageStr)
+	mainFlags.PrintDefaults()
 	os.Exit(1)
 }
 
+// Global options, shared by every subcommand.
+var (
+	mainFlags  = pflag.NewFlagSet("kapacitor", pflag.ContinueOnError)
+	mainURL    = mainFlags.StringP("url", "u", "", "the URL of the kapacitord server. Defaults to $KAPACITOR_URL, or the 'url' entry in ~/.kapacitor/config, or http://localhost:9092")
+	skipVerify = mainFlags.Bool("skip-verify", false, "disable SSL certificate verification when connecting to kapacitord")
+	caCert     = mainFlags.String("ca-cert", "", "path to a PEM encoded CA certificate to trust, for connecting to a TLS-terminated kapacitord")
+)
+
+// client is the configured connection to kapacitord used by every command.
+var client *Client
+
+// command associates a verb with the flag set that parses its arguments
+// and the function that implements it, so that adding a new verb only
+// means appending an entry here instead of growing a switch statement.
+type command struct {
+	name  string
+	flags *pflag.FlagSet
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"help", nil, doHelp},
+	{"record", nil, doRecordCmd},
+	{"define", defineFlags, doDefine},
+	{"replay", replayFlags, doReplay},
+	{"enable", nil, doEnable},
+	{"disable", nil, doDisable},
+	{"push", pushFlags, doPush},
+	{"delete", nil, doDelete},
+	{"list", listFlags, doList},
+	{"stats", statsFlags, doStats},
+	{"watch", watchFlags, doWatch},
+	{"level", nil, doLevel},
+	{"version", nil, doVersion},
+}
+
+func lookupCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
 func main() {
+	// Stop scanning for global flags at the first non-flag argument (the
+	// subcommand name) so subcommand-specific flags are left in Args() for
+	// the subcommand's own FlagSet to parse, instead of being rejected here
+	// as unknown flags.
+	mainFlags.SetInterspersed(false)
+	if err := mainFlags.Parse(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	args := mainFlags.Args()
 
-	if len(os.Args) == 1 {
+	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: Must pass a command.")
 		usage()
 	}
 
-	command := os.Args[1]
-	args := os.Args[2:]
-	var commandF func(args []string) error
-	var commandArgs []string
-	switch command {
-	case "help":
-		commandArgs = args
-		commandF = doHelp
-	case "record":
-		if len(args) == 0 {
-			recordFlags.Usage()
-			os.Exit(2)
-		}
-		recordFlags.Parse(args[1:])
-		commandArgs = args[0:1]
-		commandF = doRecord
-	case "define":
-		defineFlags.Parse(args)
-		commandArgs = defineFlags.Args()
-		commandF = doDefine
-	case "replay":
-		replayFlags.Parse(args)
-		commandArgs = replayFlags.Args()
-		commandF = doReplay
-	case "enable":
-		commandArgs = args
-		commandF = doEnable
-	case "disable":
-		commandArgs = args
-		commandF = doDisable
-	case "delete":
-		commandArgs = args
-		commandF = doDelete
-	case "list":
-		commandArgs = args
-		commandF = doList
-	case "level":
-		commandArgs = args
-		commandF = doLevel
-	case "version":
-		commandArgs = args
-		commandF = doVersion
-	default:
-		fmt.Fprintln(os.Stderr, "Unknown command", command)
+	c, err := newClient()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(3)
+	}
+	client = c
+
+	name := args[0]
+	cmdArgs := args[1:]
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		fmt.Fprintln(os.Stderr, "Unknown command", name)
 		usage()
 	}
 
-	err := commandF(commandArgs)
-	if err != nil {
+	if cmd.flags != nil {
+		cmd.flags.Parse(cmdArgs)
+		cmdArgs = cmd.flags.Args()
+	}
+
+	if err := cmd.run(cmdArgs); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(3)
 	}
@@ -114,6 +155,110 @@ func init() {
 	replayFlags.Usage = replayUsage
 	defineFlags.Usage = defineUsage
 	recordFlags.Usage = recordUsage
+	statsFlags.Usage = statsUsage
+	watchFlags.Usage = watchUsage
+	pushFlags.Usage = pushUsage
+	listFlags.Usage = listUsage
+}
+
+// Client is a thin wrapper around http.Client that knows the address of a
+// kapacitord server and how to reach it, so that individual commands don't
+// each have to hard code a URL and a TLS configuration.
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// fileConfig is the shape of ~/.kapacitor/config, allowing operators to
+// avoid repeating --url/--ca-cert on every invocation.
+type fileConfig struct {
+	URL        string `yaml:"url"`
+	SkipVerify bool   `yaml:"skip-verify"`
+	CACert     string `yaml:"ca-cert"`
+}
+
+func loadFileConfig() fileConfig {
+	var cfg fileConfig
+	home := os.Getenv("HOME")
+	if home == "" {
+		return cfg
+	}
+	b, err := ioutil.ReadFile(filepath.Join(home, ".kapacitor", "config"))
+	if err != nil {
+		return cfg
+	}
+	yaml.Unmarshal(b, &cfg)
+	return cfg
+}
+
+// newClient builds the Client used for the lifetime of the process from,
+// in order of precedence, command line flags, the KAPACITOR_URL
+// environment variable, ~/.kapacitor/config, and finally the built-in
+// default of http://localhost:9092.
+func newClient() (*Client, error) {
+	cfg := loadFileConfig()
+
+	addr := *mainURL
+	if addr == "" {
+		addr = os.Getenv("KAPACITOR_URL")
+	}
+	if addr == "" {
+		addr = cfg.URL
+	}
+	if addr == "" {
+		addr = "http://localhost:9092"
+	}
+
+	insecure := *skipVerify || cfg.SkipVerify
+
+	cert := *caCert
+	if cert == "" {
+		cert = cfg.CACert
+	}
+
+	return newClientWithAddr(addr, insecure, cert)
+}
+
+// newClientWithAddr builds a Client for an arbitrary kapacitord address
+// with the given TLS settings. 'push' uses it to talk to a second, remote
+// instance, passing only the --skip-verify and --ca-cert flag values; the
+// TLS settings in ~/.kapacitor/config are not applied to the remote.
+func newClientWithAddr(addr string, insecure bool, cert string) (*Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if cert != "" {
+		pem, err := ioutil.ReadFile(cert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA certificate %q: %s", cert, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("unable to parse CA certificate %q", cert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &Client{
+		url: strings.TrimRight(addr, "/"),
+		httpClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+func (c *Client) Get(path string, v url.Values) (*http.Response, error) {
+	return c.httpClient.Get(c.url + path + "?" + v.Encode())
+}
+
+func (c *Client) Post(path string, v url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	return c.httpClient.Post(c.url+path+"?"+v.Encode(), contentType, body)
+}
+
+func (c *Client) Delete(path string, v url.Values) (*http.Response, error) {
+	req, err := http.NewRequest("DELETE", c.url+path+"?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient.Do(req)
 }
 
 // helper methods
@@ -135,30 +280,27 @@ func helpUsage() {
 
 func doHelp(args []string) error {
 	if len(args) == 1 {
-		command := args[0]
-		switch command {
-		case "record":
-			recordFlags.Usage()
-		case "define":
-			defineFlags.Usage()
-		case "replay":
-			replayFlags.Usage()
-		case "enable":
+		name := args[0]
+		cmd := lookupCommand(name)
+		switch {
+		case name == "help":
+			helpUsage()
+		case cmd != nil && cmd.flags != nil:
+			cmd.flags.Usage()
+		case name == "record":
+			recordUsage()
+		case name == "enable":
 			enableUsage()
-		case "disable":
+		case name == "disable":
 			disableUsage()
-		case "delete":
+		case name == "delete":
 			deleteUsage()
-		case "list":
-			listUsage()
-		case "level":
+		case name == "level":
 			levelUsage()
-		case "help":
-			helpUsage()
-		case "version":
+		case name == "version":
 			versionUsage()
 		default:
-			fmt.Fprintln(os.Stderr, "Unknown command", command)
+			fmt.Fprintln(os.Stderr, "Unknown command", name)
 			usage()
 		}
 	} else {
@@ -169,16 +311,16 @@ func doHelp(args []string) error {
 
 // Record
 var (
-	recordFlags = flag.NewFlagSet("record", flag.ExitOnError)
-	raddr       = recordFlags.String("addr", "", "the URL address of the InfluxDB server. If recording a batch or query.")
-	rname       = recordFlags.String("name", "", "the name of a task. If recording a batch")
+	recordFlags = pflag.NewFlagSet("record", pflag.ExitOnError)
+	raddr       = recordFlags.StringP("addr", "a", "", "the URL address of the InfluxDB server. If recording a batch or query.")
+	rname       = recordFlags.StringP("name", "n", "", "the name of a task. If recording a batch")
 	rstart      = recordFlags.String("start", "", "the start time of a task query.")
 	rnum        = recordFlags.Int("num", 1, "the number of periods to query. If recording a batch")
 
 	rquery = recordFlags.String("query", "", "the query to record. If recording a query.")
-	rtype  = recordFlags.String("type", "", "the type of the recording to save (streamer|batcher). If recording a query.")
+	rtype  = recordFlags.StringP("type", "t", "", "the type of the recording to save (streamer|batcher). If recording a query.")
 
-	rdur = recordFlags.Duration("duration", time.Minute*5, "how long to record the data stream. If recording a stream.")
+	rdur = recordFlags.DurationP("duration", "d", time.Minute*5, "how long to record the data stream. If recording a stream.")
 )
 
 func recordUsage() {
@@ -194,18 +336,18 @@ func recordUsage() {
 
 Examples:
 
-	$ kapacitor record stream -duration 1m
+	$ kapacitor record stream --duration 1m
 
 		This records the live data stream for 1 minute.
-	
-	$ kapacitor record batch -addr 'http://localhost:8086' -name cpu_idle -start 2015-09-01T00:00:00Z -num 10
-		
+
+	$ kapacitor record batch --addr 'http://localhost:8086' --name cpu_idle --start 2015-09-01T00:00:00Z --num 10
+
 		This records the result of the query defined in task 'cpu_idle' and runs the query 10 times
 		starting at time 'start' and incrementing by the period defined in the task.
 
-	$ kapacitor record query -addr 'http://localhost:8086' -query "select value from cpu_idle where time > now() - 1h and time < now()" -type streamer
+	$ kapacitor record query --addr 'http://localhost:8086' --query "select value from cpu_idle where time > now() - 1h and time < now()" --type streamer
 
-		This records the result of the query and stores it as a stream recording. Use -type batcher to store as batch recording.
+		This records the result of the query and stores it as a stream recording. Use --type batcher to store as batch recording.
 
 Options:
 `
@@ -213,6 +355,17 @@ Options:
 	recordFlags.PrintDefaults()
 }
 
+// doRecordCmd parses the record type positional argument before the rest
+// of the flags, since it must come first on the command line.
+func doRecordCmd(args []string) error {
+	if len(args) == 0 {
+		recordFlags.Usage()
+		os.Exit(2)
+	}
+	recordFlags.Parse(args[1:])
+	return doRecord(args[0:1])
+}
+
 func doRecord(args []string) error {
 
 	v := url.Values{}
@@ -231,7 +384,7 @@ func doRecord(args []string) error {
 	default:
 		return fmt.Errorf("Unknown record type %q, expected 'stream' or 'query'", args[0])
 	}
-	r, err := http.Post("http://localhost:9092/record?"+v.Encode(), "application/octetstream", nil)
+	r, err := client.Post("/record", v, "application/octetstream", nil)
 	if err != nil {
 		return err
 	}
@@ -254,10 +407,10 @@ func doRecord(args []string) error {
 
 // Define
 var (
-	defineFlags = flag.NewFlagSet("define", flag.ExitOnError)
-	dname       = defineFlags.String("name", "", "the task name")
-	dtick       = defineFlags.String("tick", "", "path to the TICK script")
-	dtype       = defineFlags.String("type", "", "the task type (streamer|batcher)")
+	defineFlags = pflag.NewFlagSet("define", pflag.ExitOnError)
+	dname       = defineFlags.StringP("name", "n", "", "the task name")
+	dtick       = defineFlags.StringP("tick", "t", "", "path to the TICK script")
+	dtype       = defineFlags.StringP("type", "y", "", "the task type (streamer|batcher)")
 )
 
 func defineUsage() {
@@ -288,7 +441,7 @@ func doDefine(args []string) error {
 	v := url.Values{}
 	v.Add("name", *dname)
 	v.Add("type", *dtype)
-	r, err := http.Post("http://localhost:9092/task?"+v.Encode(), "application/octetstream", f)
+	r, err := client.Post("/task", v, "application/octetstream", f)
 	if err != nil {
 		return err
 	}
@@ -309,8 +462,8 @@ func doDefine(args []string) error {
 
 // Replay
 var (
-	replayFlags = flag.NewFlagSet("replay", flag.ExitOnError)
-	rtname      = replayFlags.String("name", "", "the task name")
+	replayFlags = pflag.NewFlagSet("replay", pflag.ExitOnError)
+	rtname      = replayFlags.StringP("name", "n", "", "the task name")
 	rid         = replayFlags.String("id", "", "the recording ID")
 	rfast       = replayFlags.Bool("fast", false, "whether to replay the data as fast as possible. If false, replay the data in real time")
 )
@@ -337,7 +490,7 @@ func doReplay(args []string) error {
 	if *rfast {
 		v.Add("clock", "fast")
 	}
-	r, err := http.Post("http://localhost:9092/replay?"+v.Encode(), "application/octetstream", nil)
+	r, err := client.Post("/replay", v, "application/octetstream", nil)
 	if err != nil {
 		return err
 	}
@@ -375,7 +528,7 @@ func doEnable(args []string) error {
 	for _, name := range args {
 		v := url.Values{}
 		v.Add("name", name)
-		r, err := http.Post("http://localhost:9092/enable?"+v.Encode(), "application/octetstream", nil)
+		r, err := client.Post("/enable", v, "application/octetstream", nil)
 		if err != nil {
 			return err
 		}
@@ -414,7 +567,7 @@ func doDisable(args []string) error {
 	for _, name := range args {
 		v := url.Values{}
 		v.Add("name", name)
-		r, err := http.Post("http://localhost:9092/disable?"+v.Encode(), "application/octetstream", nil)
+		r, err := client.Post("/disable", v, "application/octetstream", nil)
 		if err != nil {
 			return err
 		}
@@ -435,16 +588,40 @@ func doDisable(args []string) error {
 }
 
 // List
+var (
+	listFlags = pflag.NewFlagSet("list", pflag.ExitOnError)
+	loFormat  = listFlags.StringP("output", "o", "table", "output format: json, table, or tsv")
+	loFilter  = listFlags.String("filter", "", "only list entries whose name/ID matches this glob or regex pattern")
+)
 
 func listUsage() {
-	var u = `Usage: kapacitor list (tasks|recordings) [task name|recording ID]...
+	var u = `Usage: kapacitor list (tasks|recordings) [task name|recording ID]... [options]
 
 List tasks or recordings and their current state.
 
 If no tasks are given then all tasks are listed. Same for recordings.
 If a set of task names or recordings IDs is provided only those entries will be listed.
+Use --filter to select entries by glob or regex pattern instead.
+
+Options:
 `
 	fmt.Fprintln(os.Stderr, u)
+	listFlags.PrintDefaults()
+}
+
+// matchesFilter reports whether name satisfies pattern, trying it first as
+// a shell glob and then, if that isn't a match, as a regular expression.
+func matchesFilter(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	if ok, err := filepath.Match(pattern, name); err == nil && ok {
+		return true
+	}
+	if re, err := regexp.Compile(pattern); err == nil {
+		return re.MatchString(name)
+	}
+	return false
 }
 
 func doList(args []string) error {
@@ -460,19 +637,23 @@ func doList(args []string) error {
 		tasks := strings.Join(args[1:], ",")
 		v := url.Values{}
 		v.Add("tasks", tasks)
-		r, err := http.Get("http://localhost:9092/tasks?" + v.Encode())
+		r, err := client.Get("/tasks", v)
 		if err != nil {
 			return err
 		}
 		defer r.Body.Close()
 		// Decode valid response
+		type task struct {
+			Name       string
+			Type       kapacitor.TaskType
+			Enabled    bool
+			Created    time.Time
+			Modified   time.Time
+			LastReplay time.Time
+		}
 		type resp struct {
 			Error string `json:"Error"`
-			Tasks []struct {
-				Name    string
-				Type    kapacitor.TaskType
-				Enabled bool
-			} `json:"Tasks"`
+			Tasks []task `json:"Tasks"`
 		}
 		d := json.NewDecoder(r.Body)
 		rp := resp{}
@@ -481,29 +662,50 @@ func doList(args []string) error {
 			return errors.New(rp.Error)
 		}
 
-		outFmt := "%-30s%-10v%-10v\n"
-		fmt.Fprintf(os.Stdout, outFmt, "Name", "Type", "Enabled")
+		filtered := rp.Tasks[:0]
 		for _, t := range rp.Tasks {
-			fmt.Fprintf(os.Stdout, outFmt, t.Name, t.Type, t.Enabled)
+			if matchesFilter(*loFilter, t.Name) {
+				filtered = append(filtered, t)
+			}
+		}
+
+		switch *loFormat {
+		case "json":
+			return json.NewEncoder(os.Stdout).Encode(filtered)
+		case "tsv":
+			fmt.Fprintln(os.Stdout, "Name\tType\tEnabled\tModified\tLast Replay")
+			for _, t := range filtered {
+				fmt.Fprintf(os.Stdout, "%s\t%v\t%v\t%s\t%s\n", t.Name, t.Type, t.Enabled, humanize.Time(t.Modified), humanize.Time(t.LastReplay))
+			}
+		case "table":
+			outFmt := "%-30s%-10v%-10v%-20s%-20s\n"
+			fmt.Fprintf(os.Stdout, outFmt, "Name", "Type", "Enabled", "Modified", "Last Replay")
+			for _, t := range filtered {
+				fmt.Fprintf(os.Stdout, outFmt, t.Name, t.Type, t.Enabled, humanize.Time(t.Modified), humanize.Time(t.LastReplay))
+			}
+		default:
+			return fmt.Errorf("Unknown output format %q, expected 'json', 'table', or 'tsv'", *loFormat)
 		}
 	case "recordings":
 
 		rids := strings.Join(args[1:], ",")
 		v := url.Values{}
 		v.Add("rids", rids)
-		r, err := http.Get("http://localhost:9092/recordings?" + v.Encode())
+		r, err := client.Get("/recordings", v)
 		if err != nil {
 			return err
 		}
 		defer r.Body.Close()
 		// Decode valid response
+		type recording struct {
+			ID      string
+			Type    kapacitor.TaskType
+			Size    int64
+			Created time.Time
+		}
 		type resp struct {
-			Error      string `json:"Error"`
-			Recordings []struct {
-				ID   string
-				Type kapacitor.TaskType
-				Size int64
-			} `json:"Recordings"`
+			Error      string      `json:"Error"`
+			Recordings []recording `json:"Recordings"`
 		}
 		d := json.NewDecoder(r.Body)
 		rp := resp{}
@@ -512,22 +714,383 @@ func doList(args []string) error {
 			return errors.New(rp.Error)
 		}
 
-		outFmt := "%-40s%-10v%15.2f\n"
-		fmt.Fprintf(os.Stdout, "%-40s%-10s%15s\n", "ID", "Type", "Size (MB)")
-		for _, r := range rp.Recordings {
-			fmt.Fprintf(os.Stdout, outFmt, r.ID, r.Type, float64(r.Size)/1024.0/1024.0)
+		filtered := rp.Recordings[:0]
+		for _, rec := range rp.Recordings {
+			if matchesFilter(*loFilter, rec.ID) {
+				filtered = append(filtered, rec)
+			}
+		}
+
+		switch *loFormat {
+		case "json":
+			return json.NewEncoder(os.Stdout).Encode(filtered)
+		case "tsv":
+			fmt.Fprintln(os.Stdout, "ID\tType\tSize\tCreated")
+			for _, rec := range filtered {
+				fmt.Fprintf(os.Stdout, "%s\t%v\t%s\t%s\n", rec.ID, rec.Type, humanize.Bytes(uint64(rec.Size)), humanize.Time(rec.Created))
+			}
+		case "table":
+			outFmt := "%-40s%-10v%-10s%-20s\n"
+			fmt.Fprintf(os.Stdout, outFmt, "ID", "Type", "Size", "Created")
+			for _, rec := range filtered {
+				fmt.Fprintf(os.Stdout, outFmt, rec.ID, rec.Type, humanize.Bytes(uint64(rec.Size)), humanize.Time(rec.Created))
+			}
+		default:
+			return fmt.Errorf("Unknown output format %q, expected 'json', 'table', or 'tsv'", *loFormat)
 		}
 	}
 	return nil
 
 }
 
+// Stats
+var (
+	statsFlags = pflag.NewFlagSet("stats", pflag.ExitOnError)
+)
+
+func statsUsage() {
+	var u = `Usage: kapacitor stats (general|tasks|ingress) [options]
+
+	Display runtime statistics about a running kapacitord.
+
+	Statistics are published by the server as an expvar tree and filtered
+	server-side to the requested scope.
+
+Examples:
+
+	$ kapacitor stats general
+
+		Displays general server statistics like the number of tasks defined and enabled.
+
+	$ kapacitor stats tasks
+
+		Displays per task statistics like points in/out and batch queries executed.
+
+Options:
+`
+	fmt.Fprintln(os.Stderr, u)
+	statsFlags.PrintDefaults()
+}
+
+func doStats(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Must specify a stats scope, one of 'general', 'tasks', or 'ingress'")
+		statsUsage()
+		os.Exit(2)
+	}
+
+	v := url.Values{}
+	v.Add("scope", args[0])
+	r, err := client.Get("/kapacitor/v1/stats", v)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	// Decode valid response
+	type resp struct {
+		Error string                 `json:"Error"`
+		Stats map[string]interface{} `json:"Stats"`
+	}
+	d := json.NewDecoder(r.Body)
+	rp := resp{}
+	d.Decode(&rp)
+	if rp.Error != "" {
+		return errors.New(rp.Error)
+	}
+
+	outFmt := "%-40s%v\n"
+	for k, v := range rp.Stats {
+		fmt.Fprintf(os.Stdout, outFmt, k, v)
+	}
+	return nil
+}
+
+// Watch
+var (
+	watchFlags = pflag.NewFlagSet("watch", pflag.ExitOnError)
+	wlevel     = watchFlags.String("level", "", "only show log messages at or above this level (debug|info|warn|error)")
+	wsince     = watchFlags.String("since", "", "only show events that occurred since this RFC3339 timestamp")
+	wjson      = watchFlags.Bool("json", false, "print each event as a raw JSON object instead of a formatted line")
+)
+
+func watchUsage() {
+	var u = `Usage: kapacitor watch [task name...] [options]
+
+	Stream logs and alert events for one or more tasks as they happen.
+
+	The connection stays open and events are printed as they arrive, similar to 'docker logs -f'.
+	If no task names are given, events for all tasks are streamed.
+
+Options:
+`
+	fmt.Fprintln(os.Stderr, u)
+	watchFlags.PrintDefaults()
+}
+
+func doWatch(args []string) error {
+	v := url.Values{}
+	if len(args) > 0 {
+		v.Add("name", strings.Join(args, ","))
+	}
+	if *wlevel != "" {
+		v.Add("level", *wlevel)
+	}
+	if *wsince != "" {
+		v.Add("since", *wsince)
+	}
+	r, err := client.Get("/kapacitor/v1/watch", v)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	type event struct {
+		Time    time.Time `json:"Time"`
+		Task    string    `json:"Task"`
+		Level   string    `json:"Level"`
+		Kind    string    `json:"Kind"`
+		Message string    `json:"Message"`
+	}
+
+	s := bufio.NewScanner(r.Body)
+	for s.Scan() {
+		line := s.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		data := strings.TrimPrefix(line, "data: ")
+		if *wjson {
+			fmt.Fprintln(os.Stdout, data)
+			continue
+		}
+		var e event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			// A single malformed event (e.g. a keepalive or partial
+			// chunk) shouldn't tear down a long-lived watch stream.
+			fmt.Fprintf(os.Stderr, "watch: skipping malformed event: %s\n", err)
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%s [%s] %s %s: %s\n", e.Time.Format(time.RFC3339), e.Level, e.Task, e.Kind, e.Message)
+	}
+	return s.Err()
+}
+
+// Push
+var (
+	pushFlags = pflag.NewFlagSet("push", pflag.ExitOnError)
+	pname     = pushFlags.StringP("name", "n", "", "the name of the task to push")
+	premote   = pushFlags.StringP("remote", "r", "", "the URL of the remote Kapacitor instance to push to")
+	penable   = pushFlags.Bool("enable", false, "enable the task on the remote instance after pushing")
+	pall      = pushFlags.Bool("all", false, "push every local task instead of a single named task")
+	pdryRun   = pushFlags.Bool("dry-run", false, "show what would change without uploading anything")
+)
+
+func pushUsage() {
+	var u = `Usage: kapacitor push --remote <url> (--name <task name>|--all) [options]
+
+	Publish a task definition to another Kapacitor instance.
+
+	The local TICK script and type for the given task are read from this
+	instance and uploaded to the remote instance under the same name.
+
+Examples:
+
+	$ kapacitor push --remote http://prod:9092 --name cpu_alert --enable
+
+		Pushes the 'cpu_alert' task to the remote instance and enables it there.
+
+	$ kapacitor push --remote http://prod:9092 --all --dry-run
+
+		Shows how every local task's TICK script differs from the remote's, without uploading.
+
+Options:
+`
+	fmt.Fprintln(os.Stderr, u)
+	pushFlags.PrintDefaults()
+}
+
+// taskDef is the shape of the /task endpoint's response, extended to
+// carry the raw TICK script so it can be replayed onto another instance.
+type taskDef struct {
+	Name       string             `json:"Name"`
+	Type       kapacitor.TaskType `json:"Type"`
+	TICKscript string             `json:"TICKscript"`
+	Error      string             `json:"Error"`
+}
+
+func fetchTask(c *Client, name string) (*taskDef, error) {
+	v := url.Values{}
+	v.Add("name", name)
+	r, err := c.Get("/task", v)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	d := json.NewDecoder(r.Body)
+	t := taskDef{}
+	d.Decode(&t)
+	if t.Error != "" {
+		return nil, errors.New(t.Error)
+	}
+	return &t, nil
+}
+
+func fetchTaskNames(c *Client) ([]string, error) {
+	r, err := c.Get("/tasks", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	type resp struct {
+		Error string `json:"Error"`
+		Tasks []struct {
+			Name string
+		} `json:"Tasks"`
+	}
+	d := json.NewDecoder(r.Body)
+	rp := resp{}
+	d.Decode(&rp)
+	if rp.Error != "" {
+		return nil, errors.New(rp.Error)
+	}
+
+	names := make([]string, len(rp.Tasks))
+	for i, t := range rp.Tasks {
+		names[i] = t.Name
+	}
+	return names, nil
+}
+
+// diffScripts prints a line-by-line comparison of a task's local and
+// remote TICK scripts for 'push --dry-run'.
+func diffScripts(name, local, remote string) {
+	if local == remote {
+		fmt.Fprintf(os.Stdout, "%s: up to date\n", name)
+		return
+	}
+	fmt.Fprintf(os.Stdout, "%s: local and remote TICK scripts differ\n", name)
+	localLines := strings.Split(local, "\n")
+	remoteLines := strings.Split(remote, "\n")
+	max := len(localLines)
+	if len(remoteLines) > max {
+		max = len(remoteLines)
+	}
+	for i := 0; i < max; i++ {
+		var l, r string
+		if i < len(localLines) {
+			l = localLines[i]
+		}
+		if i < len(remoteLines) {
+			r = remoteLines[i]
+		}
+		if l == r {
+			continue
+		}
+		if l != "" {
+			fmt.Fprintf(os.Stdout, "- %s\n", l)
+		}
+		if r != "" {
+			fmt.Fprintf(os.Stdout, "+ %s\n", r)
+		}
+	}
+}
+
+func pushTask(name string, remote *Client) error {
+	local, err := fetchTask(client, name)
+	if err != nil {
+		return err
+	}
+
+	if *pdryRun {
+		remoteTask, err := fetchTask(remote, name)
+		var remoteScript string
+		if err == nil {
+			remoteScript = remoteTask.TICKscript
+		}
+		diffScripts(name, local.TICKscript, remoteScript)
+		return nil
+	}
+
+	v := url.Values{}
+	v.Add("name", name)
+	v.Add("type", local.Type.String())
+	r, err := remote.Post("/task", v, "application/octetstream", strings.NewReader(local.TICKscript))
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	type resp struct {
+		Error string `json:"Error"`
+	}
+	d := json.NewDecoder(r.Body)
+	rp := resp{}
+	d.Decode(&rp)
+	if rp.Error != "" {
+		return errors.New(rp.Error)
+	}
+
+	if *penable {
+		ev := url.Values{}
+		ev.Add("name", name)
+		er, err := remote.Post("/enable", ev, "application/octetstream", nil)
+		if err != nil {
+			return err
+		}
+		defer er.Body.Close()
+		erp := resp{}
+		json.NewDecoder(er.Body).Decode(&erp)
+		if erp.Error != "" {
+			return errors.New(erp.Error)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "%s: pushed\n", name)
+	return nil
+}
+
+func doPush(args []string) error {
+	if *premote == "" {
+		fmt.Fprintln(os.Stderr, "Must pass --remote")
+		pushFlags.Usage()
+		os.Exit(2)
+	}
+	if !*pall && *pname == "" {
+		fmt.Fprintln(os.Stderr, "Must pass --name or --all")
+		pushFlags.Usage()
+		os.Exit(2)
+	}
+
+	remote, err := newClientWithAddr(*premote, *skipVerify, *caCert)
+	if err != nil {
+		return err
+	}
+
+	names := []string{*pname}
+	if *pall {
+		names, err = fetchTaskNames(client)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if err := pushTask(name, remote); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Delete
 func deleteUsage() {
 	var u = `Usage: kapacitor delete (task|recording) [task name|recording ID]...
 
 	Delete a task or recording.
-	
+
 	If a task is enabled it will be disabled and then deleted.
 `
 	fmt.Fprintln(os.Stderr, u)
@@ -540,14 +1103,14 @@ func doDelete(args []string) error {
 		os.Exit(2)
 	}
 
-	var baseURL string
+	var basePath string
 	var paramName string
 	switch args[0] {
 	case "task":
-		baseURL = "http://localhost:9092/task"
+		basePath = "/task"
 		paramName = "name"
 	case "recording":
-		baseURL = "http://localhost:9092/recording"
+		basePath = "/recording"
 		paramName = "rid"
 	}
 
@@ -556,12 +1119,7 @@ func doDelete(args []string) error {
 	for _, arg := range args[1:] {
 		v := url.Values{}
 		v.Add(paramName, arg)
-		req, err := http.NewRequest("DELETE", baseURL+v.Encode(), nil)
-		if err != nil {
-			return err
-		}
-		client := &http.Client{}
-		r, err := client.Do(req)
+		r, err := client.Delete(basePath, v)
 		if err != nil {
 			return err
 		}
@@ -598,7 +1156,7 @@ func doLevel(args []string) error {
 	}
 	v := url.Values{}
 	v.Add("level", args[0])
-	r, err := http.Post("http://localhost:9092/loglevel?"+v.Encode(), "text/plain", nil)
+	r, err := client.Post("/loglevel", v, "text/plain", nil)
 	if err != nil {
 		return err
 	}
@@ -628,4 +1186,4 @@ func versionUsage() {
 func doVersion(args []string) error {
 	fmt.Fprintf(os.Stdout, "Kapacitor %s (git: %s %s)\n", version, branch, commit)
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestMatchesFilter(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"", "cpu_alert", true},
+		{"cpu_alert", "cpu_alert", true},
+		{"cpu_alert", "mem_alert", false},
+		{"cpu_*", "cpu_alert", true},
+		{"cpu_*", "mem_alert", false},
+		{"cpu_[a-z]+", "cpu_alert", true},
+		{"cpu_[0-9]+", "cpu_alert", false},
+		{"[", "cpu_alert", false},
+	}
+	for _, tt := range tests {
+		if got := matchesFilter(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchesFilter(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+// captureStdout runs fn with os.Stdout redirected to a pipe and returns
+// whatever was written to it.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %s", err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	fn()
+
+	w.Close()
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("io.Copy: %s", err)
+	}
+	return buf.String()
+}
+
+func TestDiffScriptsUpToDate(t *testing.T) {
+	out := captureStdout(t, func() {
+		diffScripts("cpu_alert", "stream\n    |alert()", "stream\n    |alert()")
+	})
+	want := "cpu_alert: up to date\n"
+	if out != want {
+		t.Errorf("diffScripts() output = %q, want %q", out, want)
+	}
+}
+
+func TestDiffScriptsDiffers(t *testing.T) {
+	out := captureStdout(t, func() {
+		diffScripts("cpu_alert", "stream\n    |alert().crit(90)", "stream\n    |alert().crit(80)")
+	})
+	want := "cpu_alert: local and remote TICK scripts differ\n" +
+		"-     |alert().crit(90)\n" +
+		"+     |alert().crit(80)\n"
+	if out != want {
+		t.Errorf("diffScripts() output = %q, want %q", out, want)
+	}
+}